@@ -0,0 +1,115 @@
+package mongopagination
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type countKind int
+
+const (
+	countKindExact countKind = iota
+	countKindEstimated
+	countKindNone
+	countKindCached
+)
+
+// CountMode controls how Find/Aggregate compute PaginationData.Total. The
+// zero value is CountExact, so existing callers that never call Count
+// keep today's behavior.
+type CountMode struct {
+	kind countKind
+	ttl  time.Duration
+}
+
+var (
+	// CountExact counts matching documents exactly, via CountDocuments
+	// for Find or the aggregate pipeline's own $facet "total" branch for
+	// Aggregate. This is the default and is correct, but on large
+	// collections it's often the dominant cost of the query.
+	CountExact = CountMode{kind: countKindExact}
+
+	// CountEstimated swaps the exact count for
+	// Collection.EstimatedDocumentCount, which reads collection metadata
+	// instead of scanning documents. For Aggregate this also drops the
+	// $facet "total" branch. Much cheaper, but ignores the filter/pipeline
+	// and is only a collection-wide estimate.
+	CountEstimated = CountMode{kind: countKindEstimated}
+
+	// CountNone skips counting entirely: Total/TotalPage stay 0. HasNext
+	// is populated instead via an n+1 probe, fetching one extra document
+	// past the page limit.
+	CountNone = CountMode{kind: countKindNone}
+)
+
+// CountCached memoizes the exact count per filter (or aggregate pipeline)
+// for ttl, so repeatedly paging through the same query only counts once
+// per ttl window. The cache key is a hash of the marshaled query; a bson.M
+// with several keys marshals in map iteration order, so the same such
+// query can hash differently and count again.
+func CountCached(ttl time.Duration) CountMode {
+	return CountMode{kind: countKindCached, ttl: ttl}
+}
+
+type countCacheEntry struct {
+	total     int64
+	expiresAt time.Time
+}
+
+var countCache sync.Map // key -> countCacheEntry
+
+// countCacheKey fingerprints a collection plus an arbitrary BSON-shaped
+// query (a filter for Find, a pipeline for Aggregate) into a cache key.
+// query is wrapped in a document before marshaling since bson.Marshal
+// requires a top-level document and an Aggregate pipeline is a bare slice.
+func countCacheKey(collection *mongo.Collection, query interface{}) (string, error) {
+	raw, err := bson.Marshal(bson.M{"q": query})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return collection.Database().Name() + "." + collection.Name() + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// cachedCount resolves total via the countCache, calling exactCount (and
+// storing its result for ttl) on a miss or expiry.
+func cachedCount(collection *mongo.Collection, query interface{}, ttl time.Duration, exactCount func() (int64, error)) (int64, error) {
+	key, err := countCacheKey(collection, query)
+	if err != nil {
+		return exactCount()
+	}
+	if v, ok := countCache.Load(key); ok {
+		if entry := v.(countCacheEntry); time.Now().Before(entry.expiresAt) {
+			return entry.total, nil
+		}
+	}
+	total, err := exactCount()
+	if err != nil {
+		return 0, err
+	}
+	countCache.Store(key, countCacheEntry{total: total, expiresAt: time.Now().Add(ttl)})
+	return total, nil
+}
+
+// findCount resolves PaginationData.Total for Find according to paging's
+// CountMode.
+func findCount(ctx context.Context, paging *pagingQuery) (int64, error) {
+	switch paging.Mode.kind {
+	case countKindEstimated:
+		return paging.Collection.EstimatedDocumentCount(ctx)
+	case countKindNone:
+		return 0, nil
+	case countKindCached:
+		return cachedCount(paging.Collection, paging.FilterQuery, paging.Mode.ttl, func() (int64, error) {
+			return paging.Collection.CountDocuments(ctx, paging.FilterQuery)
+		})
+	default:
+		return paging.Collection.CountDocuments(ctx, paging.FilterQuery)
+	}
+}
@@ -0,0 +1,92 @@
+package mongopagination
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func testCollection(t *testing.T) *mongo.Collection {
+	t.Helper()
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("mongo.Connect: %v", err)
+	}
+	return client.Database("count_test").Collection("docs")
+}
+
+// countCacheKey must be computable for an Aggregate pipeline ([]bson.M,
+// a bare slice), not just a Find filter (a document), since bson.Marshal
+// errors on anything that isn't a top-level document.
+func TestCountCacheKeyAggregatePipeline(t *testing.T) {
+	coll := testCollection(t)
+	pipeline := []bson.M{{"$match": bson.M{"status": "active"}}}
+
+	key, err := countCacheKey(coll, pipeline)
+	if err != nil {
+		t.Fatalf("countCacheKey returned error for a pipeline: %v", err)
+	}
+	if key == "" {
+		t.Fatal("countCacheKey returned an empty key")
+	}
+}
+
+// TestCachedCountAggregateReusesWithinTTL covers the headline use case for
+// CountCached with Aggregate: a second call for the same pipeline within
+// the TTL must not re-run exactCount.
+func TestCachedCountAggregateReusesWithinTTL(t *testing.T) {
+	coll := testCollection(t)
+	pipeline := []bson.M{{"$match": bson.M{"status": "active"}}}
+
+	var calls int
+	exactCount := func() (int64, error) {
+		calls++
+		return 42, nil
+	}
+
+	total, err := cachedCount(coll, pipeline, time.Minute, exactCount)
+	if err != nil {
+		t.Fatalf("cachedCount: %v", err)
+	}
+	if total != 42 {
+		t.Fatalf("total = %d, want 42", total)
+	}
+	if calls != 1 {
+		t.Fatalf("exactCount called %d times on first call, want 1", calls)
+	}
+
+	total, err = cachedCount(coll, pipeline, time.Minute, exactCount)
+	if err != nil {
+		t.Fatalf("cachedCount: %v", err)
+	}
+	if total != 42 {
+		t.Fatalf("total = %d, want 42", total)
+	}
+	if calls != 1 {
+		t.Fatalf("exactCount called %d times after a second cached call within TTL, want 1 (still memoized)", calls)
+	}
+}
+
+// A different pipeline must not hit the same cache entry.
+func TestCachedCountAggregateDistinctPipelines(t *testing.T) {
+	coll := testCollection(t)
+	var calls int
+	exactCount := func() (int64, error) {
+		calls++
+		return int64(calls), nil
+	}
+
+	if _, err := cachedCount(coll, []bson.M{{"$match": bson.M{"a": 1}}}, time.Minute, exactCount); err != nil {
+		t.Fatalf("cachedCount: %v", err)
+	}
+	if _, err := cachedCount(coll, []bson.M{{"$match": bson.M{"a": 2}}}, time.Minute, exactCount); err != nil {
+		t.Fatalf("cachedCount: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("exactCount called %d times for two distinct pipelines, want 2", calls)
+	}
+}
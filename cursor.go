@@ -0,0 +1,112 @@
+package mongopagination
+
+import (
+	"encoding/base64"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CursorDecodeError is returned when a supplied cursor token cannot be
+// decoded back into its sort-key/_id pair.
+const CursorDecodeError = "cursor token is invalid or corrupted"
+
+// CursorSortRequiredError is returned when FindCursor/AggregateCursor is
+// called without a sort, since keyset pagination has nothing to range
+// over otherwise.
+const CursorSortRequiredError = "Sort must be set before using FindCursor or AggregateCursor"
+
+// cursorPayload is the decoded shape of a cursor token: the last seen
+// value of each sort key plus _id, used as a tie-breaker.
+type cursorPayload struct {
+	Values bson.D      `bson:"values"`
+	ID     interface{} `bson:"_id"`
+}
+
+// encodeCursor captures the value of each key in sortDoc plus the _id of
+// doc into an opaque base64-encoded BSON token, so the next page can
+// resume right after it.
+func encodeCursor(doc bson.Raw, sortDoc bson.D) (string, error) {
+	values := make(bson.D, 0, len(sortDoc))
+	for _, key := range sortDoc {
+		val, err := doc.LookupErr(key.Key)
+		if err != nil {
+			return "", errors.Wrapf(err, "cursor sort field %q missing from document", key.Key)
+		}
+		values = append(values, bson.E{Key: key.Key, Value: val})
+	}
+	idVal, err := doc.LookupErr("_id")
+	if err != nil {
+		return "", errors.Wrap(err, "cursor _id missing from document")
+	}
+	raw, err := bson.Marshal(cursorPayload{Values: values, ID: idVal})
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor reverses encodeCursor.
+func decodeCursor(token string) (cursorPayload, error) {
+	raw, err := base64.URLEncoding.DecodeString(token)
+	if err != nil {
+		return cursorPayload{}, errors.New(CursorDecodeError)
+	}
+	var payload cursorPayload
+	if err := bson.Unmarshal(raw, &payload); err != nil {
+		return cursorPayload{}, errors.New(CursorDecodeError)
+	}
+	return payload, nil
+}
+
+// sortDirection returns -1 for a descending sort value, 1 otherwise.
+func sortDirection(value interface{}) int {
+	if v, ok := value.(int); ok && v < 0 {
+		return -1
+	}
+	return 1
+}
+
+// cursorFilter builds the range predicate that replaces $skip for keyset
+// pagination over a (possibly multi-key) sort: documents ordered after
+// the last value seen for each sort key in turn, tie-broken by _id so
+// equal sort values stay in a deterministic order across pages. This is
+// the standard seek-method predicate for compound sorts:
+//
+//	(k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... OR (k1=v1 AND ... AND _id > lastId)
+func cursorFilter(sortDoc bson.D, token string) (bson.M, error) {
+	last, err := decodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	if len(last.Values) != len(sortDoc) {
+		return nil, errors.New(CursorDecodeError)
+	}
+
+	var orConditions []bson.M
+	for i, key := range sortDoc {
+		cond := bson.M{}
+		for j := 0; j < i; j++ {
+			cond[sortDoc[j].Key] = last.Values[j].Value
+		}
+		op := "$gt"
+		if sortDirection(key.Value) < 0 {
+			op = "$lt"
+		}
+		cond[key.Key] = bson.M{op: last.Values[i].Value}
+		orConditions = append(orConditions, cond)
+	}
+
+	idCond := bson.M{}
+	for _, v := range last.Values {
+		idCond[v.Key] = v.Value
+	}
+	idOp := "$gt"
+	if len(sortDoc) > 0 && sortDirection(sortDoc[len(sortDoc)-1].Value) < 0 {
+		idOp = "$lt"
+	}
+	idCond["_id"] = bson.M{idOp: last.ID}
+	orConditions = append(orConditions, idCond)
+
+	return bson.M{"$or": orConditions}, nil
+}
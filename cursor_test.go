@@ -0,0 +1,182 @@
+package mongopagination
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEncodeDecodeCursorRoundTrip(t *testing.T) {
+	sortDoc := bson.D{{Key: "price", Value: -1}, {Key: "qty", Value: 1}}
+	id := primitive.NewObjectID()
+	raw, err := bson.Marshal(bson.M{
+		"_id":   id,
+		"price": 19.99,
+		"qty":   3,
+	})
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+
+	token, err := encodeCursor(bson.Raw(raw), sortDoc)
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+	if token == "" {
+		t.Fatal("encodeCursor returned an empty token")
+	}
+
+	payload, err := decodeCursor(token)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if len(payload.Values) != len(sortDoc) {
+		t.Fatalf("decoded %d values, want %d", len(payload.Values), len(sortDoc))
+	}
+	if payload.Values[0].Key != "price" || payload.Values[0].Value.(float64) != 19.99 {
+		t.Fatalf("values[0] = %+v, want price=19.99", payload.Values[0])
+	}
+	if payload.Values[1].Key != "qty" || payload.Values[1].Value.(int32) != 3 {
+		t.Fatalf("values[1] = %+v, want qty=3", payload.Values[1])
+	}
+	if payload.ID.(primitive.ObjectID) != id {
+		t.Fatalf("ID = %v, want %v", payload.ID, id)
+	}
+}
+
+func TestEncodeCursorMissingSortField(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{"_id": primitive.NewObjectID(), "price": 1})
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+	if _, err := encodeCursor(bson.Raw(raw), bson.D{{Key: "qty", Value: 1}}); err == nil {
+		t.Fatal("expected an error for a document missing a sort field, got nil")
+	}
+}
+
+func TestDecodeCursorInvalidToken(t *testing.T) {
+	if _, err := decodeCursor("not-valid-base64!!"); err == nil {
+		t.Fatal("expected an error for an invalid token, got nil")
+	}
+}
+
+// cursor builds a token as if the last document seen had the given sort
+// key values (in sortDoc order) and _id.
+func cursor(t *testing.T, sortDoc bson.D, id primitive.ObjectID, values ...interface{}) string {
+	t.Helper()
+	doc := bson.M{"_id": id}
+	for i, key := range sortDoc {
+		doc[key.Key] = values[i]
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+	token, err := encodeCursor(bson.Raw(raw), sortDoc)
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+	return token
+}
+
+func TestCursorFilterSingleKeyAscending(t *testing.T) {
+	sortDoc := bson.D{{Key: "qty", Value: 1}}
+	id := primitive.NewObjectID()
+	token := cursor(t, sortDoc, id, int32(5))
+
+	filter, err := cursorFilter(sortDoc, token)
+	if err != nil {
+		t.Fatalf("cursorFilter: %v", err)
+	}
+
+	or, ok := filter["$or"].([]bson.M)
+	if !ok {
+		t.Fatalf("filter[$or] = %T, want []bson.M", filter["$or"])
+	}
+	// one branch for the sort key itself, one tie-break branch on _id
+	if len(or) != 2 {
+		t.Fatalf("len(or) = %d, want 2", len(or))
+	}
+	if got := or[0]["qty"].(bson.M)["$gt"]; got != int32(5) {
+		t.Fatalf("or[0][qty][$gt] = %v, want 5", got)
+	}
+	idCond := or[1]["_id"].(bson.M)
+	if idCond["$gt"] != id {
+		t.Fatalf("or[1][_id][$gt] = %v, want %v", idCond["$gt"], id)
+	}
+	if or[1]["qty"] != int32(5) {
+		t.Fatalf("or[1][qty] = %v, want the tie-break equality on 5", or[1]["qty"])
+	}
+}
+
+func TestCursorFilterMultiKeyMixedDirection(t *testing.T) {
+	// price descending, qty ascending: a classic compound sort.
+	sortDoc := bson.D{{Key: "price", Value: -1}, {Key: "qty", Value: 1}}
+	id := primitive.NewObjectID()
+	token := cursor(t, sortDoc, id, 19.99, int32(3))
+
+	filter, err := cursorFilter(sortDoc, token)
+	if err != nil {
+		t.Fatalf("cursorFilter: %v", err)
+	}
+
+	or, ok := filter["$or"].([]bson.M)
+	if !ok {
+		t.Fatalf("filter[$or] = %T, want []bson.M", filter["$or"])
+	}
+	// one branch per sort key, plus the final _id tie-break branch
+	if len(or) != 3 {
+		t.Fatalf("len(or) = %d, want 3", len(or))
+	}
+
+	// branch 0: price < 19.99 (descending, so $lt; this is the leading key
+	// so it only ranges past the last value)
+	if got := or[0]["price"].(bson.M)["$lt"]; got != 19.99 {
+		t.Fatalf("or[0][price][$lt] = %v, want 19.99 (descending sort ranges backwards)", got)
+	}
+	if _, has := or[0]["qty"]; has {
+		t.Fatal("or[0] should not constrain qty, price alone determines this branch")
+	}
+
+	// branch 1: price == 19.99 AND qty > 3 (ascending)
+	if or[1]["price"] != 19.99 {
+		t.Fatalf("or[1][price] = %v, want equality on 19.99", or[1]["price"])
+	}
+	if got := or[1]["qty"].(bson.M)["$gt"]; got != int32(3) {
+		t.Fatalf("or[1][qty][$gt] = %v, want 3", got)
+	}
+
+	// branch 2: price == 19.99 AND qty == 3 AND _id > id (tie-break follows
+	// the last key's direction, qty is ascending so _id uses $gt)
+	if or[2]["price"] != 19.99 || or[2]["qty"] != int32(3) {
+		t.Fatalf("or[2] equality prefix = %+v, want price=19.99, qty=3", or[2])
+	}
+	if got := or[2]["_id"].(bson.M)["$gt"]; got != id {
+		t.Fatalf("or[2][_id][$gt] = %v, want %v", got, id)
+	}
+}
+
+func TestCursorFilterTieBreakFollowsLastKeyDescending(t *testing.T) {
+	sortDoc := bson.D{{Key: "createdAt", Value: -1}}
+	id := primitive.NewObjectID()
+	token := cursor(t, sortDoc, id, int32(100))
+
+	filter, err := cursorFilter(sortDoc, token)
+	if err != nil {
+		t.Fatalf("cursorFilter: %v", err)
+	}
+	or := filter["$or"].([]bson.M)
+	idCond := or[len(or)-1]["_id"].(bson.M)
+	if _, hasLt := idCond["$lt"]; !hasLt {
+		t.Fatalf("_id tie-break = %+v, want $lt since the last sort key is descending", idCond)
+	}
+}
+
+func TestCursorFilterKeyCountMismatch(t *testing.T) {
+	token := cursor(t, bson.D{{Key: "qty", Value: 1}}, primitive.NewObjectID(), int32(1))
+	_, err := cursorFilter(bson.D{{Key: "qty", Value: 1}, {Key: "price", Value: -1}}, token)
+	if err == nil {
+		t.Fatal("expected an error when the cursor's key count doesn't match sortDoc, got nil")
+	}
+}
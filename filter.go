@@ -0,0 +1,57 @@
+package mongopagination
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Filter lets callers bundle a reusable filter+sort (auth scoping, tenant
+// filtering, saved searches, ...) as a first-class value instead of
+// assembling raw bson.M at every call site. FilterWith consumes
+// FilterQuery; AggregateWith consumes Pipeline and Sort.
+type Filter interface {
+	// FilterQuery returns the filter document for FilterWith/Find.
+	FilterQuery() interface{}
+
+	// Pipeline returns the aggregation stages for AggregateWith, applied
+	// ahead of the pagination sort/skip/limit stages.
+	Pipeline() mongo.Pipeline
+
+	// Sort returns the sort document for AggregateWith. When non-empty it
+	// overrides any Sort() calls already chained on the query; when empty
+	// the chained sort is kept.
+	Sort() bson.D
+}
+
+// stageToM converts a single aggregation pipeline stage from the bson.D
+// mongo.Pipeline uses into the bson.M Aggregate's criteria expects.
+func stageToM(stage bson.D) bson.M {
+	m := make(bson.M, len(stage))
+	for _, e := range stage {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+// FilterWith sets the query's filter from filter.FilterQuery(), so a
+// reusable Filter value can be passed straight to Find/FindCursor instead
+// of calling Filter(f.FilterQuery()).
+func (paging *pagingQuery) FilterWith(filter Filter) PagingQuery {
+	paging.FilterQuery = filter.FilterQuery()
+	return paging
+}
+
+// AggregateWith runs Aggregate using filter's Pipeline instead of criteria
+// passed directly, and filter's Sort (when non-empty) instead of Sort()
+// calls chained on the query.
+func (paging *pagingQuery) AggregateWith(filter Filter) (*PaginatedData, error) {
+	if sortDoc := filter.Sort(); len(sortDoc) > 0 {
+		paging.SortDoc = sortDoc
+	}
+	pipeline := filter.Pipeline()
+	criteria := make([]interface{}, len(pipeline))
+	for i, stage := range pipeline {
+		criteria[i] = stageToM(stage)
+	}
+	return paging.Aggregate(criteria...)
+}
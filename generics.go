@@ -0,0 +1,41 @@
+package mongopagination
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// PaginatedResult is the typed counterpart of PaginatedData, for callers
+// using FindAs/AggregateAs instead of Decode.
+type PaginatedResult[T any] struct {
+	Data       []T            `json:"data"`
+	Pagination PaginationData `json:"pagination"`
+}
+
+// FindAs runs pq.Find() and unmarshals the resulting documents into a
+// slice of T, saving callers the usual bson.Raw boilerplate.
+func FindAs[T any](pq PagingQuery) (*PaginatedResult[T], error) {
+	result, err := pq.Find()
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult[T](result)
+}
+
+// AggregateAs is the Aggregate counterpart of FindAs.
+func AggregateAs[T any](pq PagingQuery, criteria ...interface{}) (*PaginatedResult[T], error) {
+	result, err := pq.Aggregate(criteria...)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult[T](result)
+}
+
+func decodeResult[T any](result *PaginatedData) (*PaginatedResult[T], error) {
+	data := make([]T, 0, len(result.Data))
+	for _, raw := range result.Data {
+		var item T
+		if err := bson.Unmarshal(raw, &item); err != nil {
+			return nil, err
+		}
+		data = append(data, item)
+	}
+	return &PaginatedResult[T]{Data: data, Pagination: result.Pagination}, nil
+}
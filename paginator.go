@@ -0,0 +1,94 @@
+package mongopagination
+
+import "context"
+
+// Paginator wraps the PaginationData computed for a query so it can be
+// passed back over a channel once the count/total work finishes.
+type Paginator struct {
+	Pagination *PaginationData
+}
+
+// PaginationData returns the underlying paging information.
+func (p *Paginator) PaginationData() *PaginationData {
+	return p.Pagination
+}
+
+// PaginationData holds the metadata returned alongside query results:
+// current page info plus, when available, total counts.
+type PaginationData struct {
+	Total     int64 `json:"total"`
+	Page      int64 `json:"page"`
+	PerPage   int64 `json:"perPage"`
+	Prev      int64 `json:"prev"`
+	Next      int64 `json:"next"`
+	TotalPage int64 `json:"totalPage"`
+
+	// NextCursor and PrevCursor are opaque keyset tokens populated by
+	// FindCursor/AggregateCursor. They're kept separate from Prev/Next
+	// (page numbers) since cursor pagination has no notion of a page.
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+
+	// HasNext reports whether another page follows. It's derived from
+	// Total/TotalPage for the default counting modes, but under
+	// CountNone it instead reflects an n+1 probe, since Total stays 0.
+	// FindCursor/AggregateCursor do not set it; it stays false there.
+	HasNext bool `json:"hasNext"`
+}
+
+// Paging computes PaginationData for the given pagingQuery and sends it on
+// paginationInfoChan. When isAggregate is true, facetCount is used as the
+// already-computed total instead of resolving one via paging.Mode; ctx is
+// propagated to whichever count call is made, so request-scoped deadlines
+// and cancellation apply to it too.
+func Paging(ctx context.Context, paging *pagingQuery, paginationInfoChan chan<- *Paginator, isAggregate bool, facetCount int64) {
+	go func() {
+		var total int64
+		if isAggregate {
+			total = facetCount
+		} else {
+			total, _ = findCount(ctx, paging)
+		}
+		totalPage := getTotalPage(total, paging.LimitCount)
+		paginationInfoChan <- &Paginator{
+			Pagination: &PaginationData{
+				Total:     total,
+				Page:      paging.PageCount,
+				PerPage:   paging.LimitCount,
+				Prev:      getPrev(paging.PageCount),
+				Next:      getNext(paging.PageCount, totalPage),
+				TotalPage: totalPage,
+				HasNext:   paging.PageCount < totalPage,
+			},
+		}
+	}()
+}
+
+// getTotalPage returns the number of pages needed to hold total documents
+// at limit documents per page.
+func getTotalPage(total, limit int64) int64 {
+	if limit <= 0 {
+		return 0
+	}
+	totalPage := total / limit
+	if total%limit != 0 {
+		totalPage++
+	}
+	return totalPage
+}
+
+// getPrev returns the previous page number, or 1 if there is none.
+func getPrev(page int64) int64 {
+	if page <= 1 {
+		return 1
+	}
+	return page - 1
+}
+
+// getNext returns the next page number, capped at totalPage.
+func getNext(page, totalPage int64) int64 {
+	if page >= totalPage {
+		return totalPage
+	}
+	return page + 1
+}
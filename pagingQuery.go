@@ -2,6 +2,8 @@ package mongopagination
 
 import (
 	"context"
+	"reflect"
+
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,6 +15,7 @@ const (
 	PageLimitError         = "page or limit cannot be less than 0"
 	FilterInAggregateError = "you cannot use filter in aggregate query but you can pass multiple filter as param in aggregate function"
 	NilFilterError         = "filter query cannot be nil"
+	DecodeTargetError      = "Decode target must be a non-nil pointer to a slice"
 )
 
 // PagingQuery struct for holding mongo
@@ -21,12 +24,15 @@ const (
 // and sort value
 type pagingQuery struct {
 	Collection  *mongo.Collection
-	SortField   string
+	SortDoc     bson.D
 	Project     interface{}
 	FilterQuery interface{}
-	SortValue   int
 	LimitCount  int64
 	PageCount   int64
+	CursorToken string
+	Target      interface{}
+	Ctx         context.Context
+	Mode        CountMode
 }
 
 // AutoGenerated is to bind Aggregate query result data
@@ -45,6 +51,14 @@ type PagingQuery interface {
 
 	Aggregate(criteria ...interface{}) (paginatedData *PaginatedData, err error)
 
+	// FindCursor is like Find but pages by a keyset cursor instead of
+	// skip/page, which stays fast on deep pages of huge collections.
+	FindCursor() (paginatedData *PaginatedData, err error)
+
+	// AggregateCursor is like Aggregate but pages by a keyset cursor
+	// instead of skip/page, and skips the total-count facet branch.
+	AggregateCursor(criteria ...interface{}) (paginatedData *PaginatedData, err error)
+
 	// Select used to enable fields which should be retrieved.
 	Select(selector interface{}) PagingQuery
 
@@ -52,6 +66,31 @@ type PagingQuery interface {
 	Limit(limit int64) PagingQuery
 	Page(page int64) PagingQuery
 	Sort(sortField string, sortValue int) PagingQuery
+
+	// Cursor sets the opaque token returned as NextCursor/PrevCursor by a
+	// previous FindCursor/AggregateCursor call, to resume from that point.
+	Cursor(token string) PagingQuery
+
+	// Decode sets a pointer to a slice to unmarshal query results into,
+	// so callers get their struct slice back directly instead of
+	// PaginatedData.Data's []bson.Raw.
+	Decode(result interface{}) PagingQuery
+
+	// Context sets the context propagated to every Mongo call this
+	// query makes, instead of the default context.Background().
+	Context(ctx context.Context) PagingQuery
+
+	// Count sets how PaginationData.Total is computed: CountExact (the
+	// default), CountEstimated, CountNone, or CountCached(ttl).
+	Count(mode CountMode) PagingQuery
+
+	// FilterWith sets the filter from a reusable Filter value, instead
+	// of Filter(f.FilterQuery()).
+	FilterWith(filter Filter) PagingQuery
+
+	// AggregateWith runs Aggregate using a reusable Filter value's
+	// Pipeline and Sort instead of criteria passed directly.
+	AggregateWith(filter Filter) (paginatedData *PaginatedData, err error)
 }
 
 // New is to construct PagingQuery object with mongo.Database and collection name
@@ -93,13 +132,81 @@ func (paging *pagingQuery) Page(page int64) PagingQuery {
 	return paging
 }
 
-// Sort is to sor mongo result by certain key
+// Sort is to sort mongo result by certain key. Calling it repeatedly
+// chains sort keys in the order they're added, e.g.
+// .Sort("price", -1).Sort("qty", -1) sorts by price then qty, both
+// descending, for a stable order across pages.
 func (paging *pagingQuery) Sort(sortField string, sortValue int) PagingQuery {
-	paging.SortField = sortField
-	paging.SortValue = sortValue
+	paging.SortDoc = append(paging.SortDoc, bson.E{Key: sortField, Value: sortValue})
+	return paging
+}
+
+// Cursor sets the keyset token to resume from, as returned by a previous
+// FindCursor/AggregateCursor call's NextCursor/PrevCursor.
+func (paging *pagingQuery) Cursor(token string) PagingQuery {
+	paging.CursorToken = token
+	return paging
+}
+
+// Decode sets the slice pointer that Find/Aggregate will unmarshal
+// results into, instead of leaving PaginatedData.Data as []bson.Raw.
+func (paging *pagingQuery) Decode(result interface{}) PagingQuery {
+	paging.Target = result
+	return paging
+}
+
+// Context sets the context propagated to every Mongo call this query
+// makes, instead of the default context.Background().
+func (paging *pagingQuery) Context(ctx context.Context) PagingQuery {
+	paging.Ctx = ctx
 	return paging
 }
 
+// ctx returns the context set via Context, falling back to
+// context.Background() so existing callers keep working unchanged.
+func (paging *pagingQuery) ctx() context.Context {
+	if paging.Ctx != nil {
+		return paging.Ctx
+	}
+	return context.Background()
+}
+
+// Count sets the counting strategy used to compute PaginationData.Total.
+func (paging *pagingQuery) Count(mode CountMode) PagingQuery {
+	paging.Mode = mode
+	return paging
+}
+
+// trimToLimit truncates sliceVal (an addressable slice value) down to
+// limit elements, reporting whether it had to. It backs the n+1 probe
+// used by CountNone to populate HasNext without a real count.
+func trimToLimit(sliceVal reflect.Value, limit int64) bool {
+	if int64(sliceVal.Len()) > limit {
+		sliceVal.Set(sliceVal.Slice(0, int(limit)))
+		return true
+	}
+	return false
+}
+
+// decodeInto unmarshals each raw document in docs into a new element
+// appended to the slice pointed to by target.
+func decodeInto(target interface{}, docs []bson.Raw) error {
+	targetVal := reflect.ValueOf(target)
+	if targetVal.Kind() != reflect.Ptr || targetVal.IsNil() || targetVal.Elem().Kind() != reflect.Slice {
+		return errors.New(DecodeTargetError)
+	}
+	sliceVal := targetVal.Elem()
+	elemType := sliceVal.Type().Elem()
+	for _, raw := range docs {
+		elemPtr := reflect.New(elemType)
+		if err := bson.Unmarshal(raw, elemPtr.Interface()); err != nil {
+			return err
+		}
+		sliceVal.Set(reflect.Append(sliceVal, elemPtr.Elem()))
+	}
+	return nil
+}
+
 // validateQuery query is to check if user has added certain required params or not
 func (paging *pagingQuery) validateQuery() error {
 	if paging.LimitCount <= 0 || paging.PageCount <= 0 {
@@ -108,6 +215,27 @@ func (paging *pagingQuery) validateQuery() error {
 	return nil
 }
 
+// aggregateCount runs filters with a trailing $count stage, for
+// CountCached's cache-miss path - cheaper than the full $facet since it
+// skips sorting/fetching the page of documents.
+func (paging *pagingQuery) aggregateCount(filters []bson.M) (int64, error) {
+	pipeline := append(append([]bson.M{}, filters...), bson.M{"$count": "count"})
+	cursor, err := paging.Collection.Aggregate(paging.ctx(), pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(paging.ctx())
+	var result struct {
+		Count int64 `bson:"count"`
+	}
+	if cursor.Next(paging.ctx()) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Count, nil
+}
+
 // Aggregate help you to paginate mongo pipeline query
 // it returns PaginatedData struct and  error if any error
 // occurs during document query
@@ -127,49 +255,105 @@ func (paging *pagingQuery) Aggregate(filters ...interface{}) (paginatedData *Pag
 	}
 	skip := getSkip(paging.PageCount, paging.LimitCount)
 
-	// making facet aggregation pipeline for result and total document count
-	facet := bson.M{"$facet": bson.M{
-		"data": []bson.M{
-			{"$sort": bson.M{"createdAt": -1}},
-			{"$skip": skip},
-			{"$limit": paging.LimitCount},
-		},
-		"total": []bson.M{{"$count": "count"}},
-	},
+	// default to sorting by createdAt when the caller hasn't set one
+	sortDoc := paging.SortDoc
+	if len(sortDoc) == 0 {
+		sortDoc = bson.D{{Key: "createdAt", Value: -1}}
+	}
+
+	// under CountNone, fetch one extra document so we can tell whether a
+	// next page exists without a real count, then trim it back off below
+	dataLimit := paging.LimitCount
+	if paging.Mode.kind == countKindNone {
+		dataLimit++
+	}
+	dataStages := []bson.M{
+		{"$sort": sortDoc},
+		{"$skip": skip},
+		{"$limit": dataLimit},
+	}
+
+	// the $facet "total" branch is only worth its cost in CountExact mode;
+	// every other mode resolves Total a cheaper way below
+	useFacetTotal := paging.Mode.kind == countKindExact
+	var pipeline []bson.M
+	pipeline = append(pipeline, aggregationFilter...)
+	if useFacetTotal {
+		pipeline = append(pipeline, bson.M{"$facet": bson.M{
+			"data":  dataStages,
+			"total": []bson.M{{"$count": "count"}},
+		}})
+	} else {
+		pipeline = append(pipeline, dataStages...)
 	}
-	aggregationFilter = append(aggregationFilter, facet)
 	diskUse := true
 	opt := &options.AggregateOptions{
 		AllowDiskUse: &diskUse,
 	}
 
-	cursor, err := paging.Collection.Aggregate(context.Background(), aggregationFilter, opt)
+	cursor, err := paging.Collection.Aggregate(paging.ctx(), pipeline, opt)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
-	var docs []AutoGenerated
-	for cursor.Next(context.Background()) {
-		var document *AutoGenerated
-		if err := cursor.Decode(&document); err == nil {
-			docs = append(docs, *document)
-		}
-	}
+	defer cursor.Close(paging.ctx())
 
 	var data []bson.Raw
 	var aggCount int64
+	if useFacetTotal {
+		var docs []AutoGenerated
+		for cursor.Next(paging.ctx()) {
+			var document *AutoGenerated
+			if err := cursor.Decode(&document); err == nil {
+				docs = append(docs, *document)
+			}
+		}
+		if len(docs) > 0 && len(docs[0].Data) > 0 {
+			aggCount = docs[0].Total[0].Count
+			data = docs[0].Data
+		}
+	} else {
+		for cursor.Next(paging.ctx()) {
+			var document bson.Raw
+			if err := cursor.Decode(&document); err == nil {
+				data = append(data, document)
+			}
+		}
+	}
 
-	if len(docs) > 0 && len(docs[0].Data) > 0 {
-		aggCount = docs[0].Total[0].Count
-		data = docs[0].Data
+	var hasNextProbe bool
+	if paging.Mode.kind == countKindNone {
+		hasNextProbe = trimToLimit(reflect.ValueOf(&data).Elem(), paging.LimitCount)
+	}
+
+	switch paging.Mode.kind {
+	case countKindEstimated:
+		if aggCount, err = paging.Collection.EstimatedDocumentCount(paging.ctx()); err != nil {
+			return nil, err
+		}
+	case countKindCached:
+		if aggCount, err = cachedCount(paging.Collection, aggregationFilter, paging.Mode.ttl, func() (int64, error) {
+			return paging.aggregateCount(aggregationFilter)
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if paging.Target != nil {
+		if err := decodeInto(paging.Target, data); err != nil {
+			return nil, err
+		}
+		data = nil
 	}
 	paginationInfoChan := make(chan *Paginator, 1)
-	Paging(paging, paginationInfoChan, true, aggCount)
+	Paging(paging.ctx(), paging, paginationInfoChan, true, aggCount)
 	paginationInfo := <-paginationInfoChan
 	result := PaginatedData{
 		Pagination: *paginationInfo.PaginationData(),
 		Data:       data,
 	}
+	if paging.Mode.kind == countKindNone {
+		result.Pagination.HasNext = hasNextProbe
+	}
 	return &result, nil
 }
 
@@ -186,30 +370,56 @@ func (paging *pagingQuery) Find() (paginatedData *PaginatedData, err error) {
 
 	// get Pagination Info
 	paginationInfoChan := make(chan *Paginator, 1)
-	Paging(paging, paginationInfoChan, false, 0)
+	Paging(paging.ctx(), paging, paginationInfoChan, false, 0)
+
+	// honor cancellation before kicking off the data fetch too, instead
+	// of only learning about it once the count/data race finishes
+	if err := paging.ctx().Err(); err != nil {
+		return nil, err
+	}
 
 	// set options for sorting and skipping
 	skip := getSkip(paging.PageCount, paging.LimitCount)
+	// under CountNone, fetch one extra document so we can tell whether a
+	// next page exists without a real count, then trim it back off below
+	fetchLimit := paging.LimitCount
+	if paging.Mode.kind == countKindNone {
+		fetchLimit++
+	}
 	opt := &options.FindOptions{
 		Skip:  &skip,
-		Limit: &paging.LimitCount,
+		Limit: &fetchLimit,
 	}
 	if paging.Project != nil {
 		opt.SetProjection(paging.Project)
 	}
-	if paging.SortField != "" && paging.SortValue != 0 {
-		opt.SetSort(bson.D{{paging.SortField, paging.SortValue}})
+	if len(paging.SortDoc) > 0 {
+		opt.SetSort(paging.SortDoc)
 	}
-	cursor, err := paging.Collection.Find(context.Background(), paging.FilterQuery, opt)
+	cursor, err := paging.Collection.Find(paging.ctx(), paging.FilterQuery, opt)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(paging.ctx())
 	var docs []bson.Raw
-	for cursor.Next(context.Background()) {
-		var document *bson.Raw
-		if err := cursor.Decode(&document); err == nil {
-			docs = append(docs, *document)
+	if paging.Target != nil {
+		if err := cursor.All(paging.ctx(), paging.Target); err != nil {
+			return nil, err
+		}
+	} else {
+		for cursor.Next(paging.ctx()) {
+			var document *bson.Raw
+			if err := cursor.Decode(&document); err == nil {
+				docs = append(docs, *document)
+			}
+		}
+	}
+	var hasNextProbe bool
+	if paging.Mode.kind == countKindNone {
+		if paging.Target != nil {
+			hasNextProbe = trimToLimit(reflect.ValueOf(paging.Target).Elem(), paging.LimitCount)
+		} else {
+			hasNextProbe = trimToLimit(reflect.ValueOf(&docs).Elem(), paging.LimitCount)
 		}
 	}
 	paginationInfo := <-paginationInfoChan
@@ -217,6 +427,151 @@ func (paging *pagingQuery) Find() (paginatedData *PaginatedData, err error) {
 		Pagination: *paginationInfo.PaginationData(),
 		Data:       docs,
 	}
+	if paging.Mode.kind == countKindNone {
+		result.Pagination.HasNext = hasNextProbe
+	}
+	return &result, nil
+}
+
+// AggregateCursor is the keyset-paginated counterpart of Aggregate. Instead
+// of a $skip/$limit window plus a $facet "total" branch, it $matches on a
+// range predicate derived from the last sort-key/_id seen (via Cursor), so
+// deep pages stay cheap on huge collections. The returned PaginationData
+// has no Total/TotalPage; it holds PerPage, the PrevCursor this page was
+// fetched with, and a NextCursor to keep paging forward.
+func (paging *pagingQuery) AggregateCursor(filters ...interface{}) (paginatedData *PaginatedData, err error) {
+	if paging.LimitCount <= 0 {
+		return nil, errors.New(PageLimitError)
+	}
+	if paging.FilterQuery != nil {
+		return nil, errors.New(FilterInAggregateError)
+	}
+	if len(paging.SortDoc) == 0 {
+		return nil, errors.New(CursorSortRequiredError)
+	}
+
+	var pipeline []bson.M
+	for _, filter := range filters {
+		pipeline = append(pipeline, filter.(bson.M))
+	}
+	if paging.CursorToken != "" {
+		rangeFilter, err := cursorFilter(paging.SortDoc, paging.CursorToken)
+		if err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, bson.M{"$match": rangeFilter})
+	}
+	tieBreak := sortDirection(paging.SortDoc[len(paging.SortDoc)-1].Value)
+	sort := append(bson.D{}, paging.SortDoc...)
+	sort = append(sort, bson.E{Key: "_id", Value: tieBreak})
+	pipeline = append(pipeline,
+		bson.M{"$sort": sort},
+		bson.M{"$limit": paging.LimitCount},
+	)
+
+	cursor, err := paging.Collection.Aggregate(paging.ctx(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(paging.ctx())
+	var docs []bson.Raw
+	for cursor.Next(paging.ctx()) {
+		var document bson.Raw
+		if err := cursor.Decode(&document); err == nil {
+			docs = append(docs, document)
+		}
+	}
+
+	var nextCursor string
+	if len(docs) > 0 {
+		if nextCursor, err = encodeCursor(docs[len(docs)-1], paging.SortDoc); err != nil {
+			return nil, err
+		}
+	}
+	if paging.Target != nil {
+		if err := decodeInto(paging.Target, docs); err != nil {
+			return nil, err
+		}
+		docs = nil
+	}
+	result := PaginatedData{
+		Data: docs,
+		Pagination: PaginationData{
+			PerPage:    paging.LimitCount,
+			PrevCursor: paging.CursorToken,
+			NextCursor: nextCursor,
+		},
+	}
+	return &result, nil
+}
+
+// FindCursor is the keyset-paginated counterpart of Find. Instead of
+// Skip, it ranges over the sort keys (and _id, as a tie-breaker) starting
+// just past the last document seen, as captured by Cursor.
+func (paging *pagingQuery) FindCursor() (paginatedData *PaginatedData, err error) {
+	if paging.LimitCount <= 0 {
+		return nil, errors.New(PageLimitError)
+	}
+	if paging.FilterQuery == nil {
+		return nil, errors.New(NilFilterError)
+	}
+	if len(paging.SortDoc) == 0 {
+		return nil, errors.New(CursorSortRequiredError)
+	}
+
+	filter := paging.FilterQuery
+	if paging.CursorToken != "" {
+		rangeFilter, err := cursorFilter(paging.SortDoc, paging.CursorToken)
+		if err != nil {
+			return nil, err
+		}
+		filter = bson.M{"$and": []interface{}{paging.FilterQuery, rangeFilter}}
+	}
+
+	tieBreak := sortDirection(paging.SortDoc[len(paging.SortDoc)-1].Value)
+	sort := append(bson.D{}, paging.SortDoc...)
+	sort = append(sort, bson.E{Key: "_id", Value: tieBreak})
+	opt := &options.FindOptions{
+		Limit: &paging.LimitCount,
+		Sort:  sort,
+	}
+	if paging.Project != nil {
+		opt.SetProjection(paging.Project)
+	}
+
+	cursor, err := paging.Collection.Find(paging.ctx(), filter, opt)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(paging.ctx())
+	var docs []bson.Raw
+	for cursor.Next(paging.ctx()) {
+		var document bson.Raw
+		if err := cursor.Decode(&document); err == nil {
+			docs = append(docs, document)
+		}
+	}
+
+	var nextCursor string
+	if len(docs) > 0 {
+		if nextCursor, err = encodeCursor(docs[len(docs)-1], paging.SortDoc); err != nil {
+			return nil, err
+		}
+	}
+	if paging.Target != nil {
+		if err := decodeInto(paging.Target, docs); err != nil {
+			return nil, err
+		}
+		docs = nil
+	}
+	result := PaginatedData{
+		Data: docs,
+		Pagination: PaginationData{
+			PerPage:    paging.LimitCount,
+			PrevCursor: paging.CursorToken,
+			NextCursor: nextCursor,
+		},
+	}
 	return &result, nil
 }
 